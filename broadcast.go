@@ -3,6 +3,9 @@ package main
 import (
 	"encoding/json"
 	"fmt"
+	"go/ast"
+	"go/token"
+	"go/types"
 	"strings"
 
 	"github.com/russross/blackfriday"
@@ -14,6 +17,25 @@ type BroadcastDoc struct {
 	Description string      `json:"description,omitempty"`
 	Samples     []DocSample `json:"samples,omitempty"`
 	PackageName string      `json:"package_name"`
+
+	exported     bool
+	reqTypeName  string
+	respTypeName string
+	typesByName  map[string]*ast.TypeSpec
+	typesPkg     *types.Package
+	pos          token.Position
+}
+
+// IsExported reports whether the declaration this doc is bound to is an
+// exported identifier.
+func (bd BroadcastDoc) IsExported() bool {
+	return bd.exported
+}
+
+// PayloadFields returns the fields of the payload type inferred from the
+// bound declaration's signature, or nil if none could be resolved.
+func (bd BroadcastDoc) PayloadFields() []StructField {
+	return resolveStructFields(bd.respTypeName, bd.typesPkg, bd.typesByName)
 }
 
 // HTMLDescription converts the description from markdown to html
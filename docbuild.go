@@ -0,0 +1,219 @@
+package main
+
+import (
+	"go/ast"
+	"go/doc"
+	"go/token"
+	"go/types"
+	"sort"
+	"strings"
+)
+
+var restMethods = map[string]bool{
+	"GET":    true,
+	"POST":   true,
+	"PUT":    true,
+	"DELETE": true,
+}
+
+// buildPackageDocs walks pkgs with go/doc and ast.CommentMap so each
+// RESTDoc/RPCDoc/BroadcastDoc is bound to the *ast.FuncDecl it annotates,
+// rather than being parsed from a comment line considered in isolation.
+// That binding is what lets a doc's request/response Go types be inferred
+// from the declaration's signature, and lets unexported declarations be
+// filtered out unless includeUnexported is set.
+func buildPackageDocs(fset *token.FileSet, pkgs map[string]*ast.Package, includeUnexported bool) map[string]*PackageDoc {
+	pkgDocs := make(map[string]*PackageDoc)
+
+	for _, astPkg := range pkgs {
+		typesByName := collectTypeSpecs(astPkg)
+		typesPkg := checkTypes(fset, astPkg)
+
+		// Build each file's CommentMap before calling doc.New: New takes
+		// ownership of the AST and strips the comments it associates with
+		// declarations, so building the map afterwards would find nothing.
+		cmaps := make(map[*ast.File]ast.CommentMap, len(astPkg.Files))
+		for _, file := range astPkg.Files {
+			cmaps[file] = ast.NewCommentMap(fset, file, file.Comments)
+		}
+
+		docPkg := doc.New(astPkg, ".", doc.AllDecls)
+
+		for _, file := range astPkg.Files {
+			cmap := cmaps[file]
+
+			ast.Inspect(file, func(n ast.Node) bool {
+				fn, ok := n.(*ast.FuncDecl)
+				if !ok {
+					return true
+				}
+				if !includeUnexported && !fn.Name.IsExported() {
+					return true
+				}
+
+				for _, cg := range cmap[fn] {
+					blocks := scanTags(cg.Text())
+					pkgBlock := firstBlock(blocks, "package")
+					if pkgBlock == nil || pkgBlock.arg == "" {
+						continue
+					}
+					pos := fset.Position(cg.Pos())
+
+					pkgDoc := pkgDocForName(pkgDocs, pkgBlock.arg)
+					if pkgDoc.Description == "" {
+						pkgDoc.Description = docPkg.Doc
+					}
+
+					switch {
+					case firstBlock(blocks, "endpoint") != nil:
+						rd := buildRESTDoc(fn, blocks, typesByName, typesPkg, pos)
+						rd.PackageName = pkgBlock.arg
+						pkgDoc.RESTDocs = append(pkgDoc.RESTDocs, rd)
+					case firstBlock(blocks, "command") != nil:
+						rpcDoc := buildRPCDoc(fn, blocks, typesByName, typesPkg, pos)
+						rpcDoc.PackageName = pkgBlock.arg
+						pkgDoc.RPCDocs = append(pkgDoc.RPCDocs, rpcDoc)
+					case firstBlock(blocks, "broadcast") != nil:
+						bcastDoc := buildBroadcastDoc(fn, blocks, typesByName, typesPkg, pos)
+						bcastDoc.PackageName = pkgBlock.arg
+						pkgDoc.BroadcastDocs = append(pkgDoc.BroadcastDocs, bcastDoc)
+					}
+				}
+
+				return true
+			})
+		}
+	}
+
+	for _, pkgDoc := range pkgDocs {
+		sort.Sort(byRPCCommand(pkgDoc.RPCDocs))
+		sort.Sort(byBroadcastName(pkgDoc.BroadcastDocs))
+	}
+
+	return pkgDocs
+}
+
+func pkgDocForName(pkgDocs map[string]*PackageDoc, name string) *PackageDoc {
+	pkgDoc := pkgDocs[name]
+	if pkgDoc == nil {
+		pkgDoc = &PackageDoc{Name: name}
+		pkgDocs[name] = pkgDoc
+	}
+	return pkgDoc
+}
+
+func buildRESTDoc(fn *ast.FuncDecl, blocks []tagBlock, typesByName map[string]*ast.TypeSpec, typesPkg *types.Package, pos token.Position) *RESTDoc {
+	rd := &RESTDoc{
+		exported:    fn.Name.IsExported(),
+		typesByName: typesByName,
+		typesPkg:    typesPkg,
+		pos:         pos,
+		Method:      "GET",
+	}
+
+	if b := firstBlock(blocks, "endpoint"); b != nil {
+		rd.Endpoint = b.arg
+	}
+	if b := firstBlock(blocks, "method"); b != nil && restMethods[b.arg] {
+		rd.Method = b.arg
+	}
+	if b := firstBlock(blocks, "description"); b != nil {
+		rd.Description = joinTagText(b)
+	}
+	if b := firstBlock(blocks, "purpose"); b != nil {
+		rd.Purpose = b.arg
+	}
+	for _, b := range blocksWithTag(blocks, "pathArg") {
+		name, description := splitArg(b.arg)
+		rd.PathArgs = append(rd.PathArgs, EndpointArgument{Name: name, Description: description})
+	}
+
+	rd.Samples = buildSamples(blocks)
+	rd.reqTypeName, rd.respTypeName = requestResponseTypeNames(fn)
+
+	return rd
+}
+
+func buildRPCDoc(fn *ast.FuncDecl, blocks []tagBlock, typesByName map[string]*ast.TypeSpec, typesPkg *types.Package, pos token.Position) *RPCDoc {
+	rpcDoc := &RPCDoc{
+		exported:    fn.Name.IsExported(),
+		typesByName: typesByName,
+		typesPkg:    typesPkg,
+		pos:         pos,
+	}
+
+	if b := firstBlock(blocks, "command"); b != nil {
+		rpcDoc.Command = strings.TrimSpace(b.arg)
+	}
+	if b := firstBlock(blocks, "description"); b != nil {
+		rpcDoc.Description = joinTagText(b)
+	}
+
+	rpcDoc.Samples = buildSamples(blocks)
+	rpcDoc.reqTypeName, rpcDoc.respTypeName = requestResponseTypeNames(fn)
+
+	return rpcDoc
+}
+
+func buildBroadcastDoc(fn *ast.FuncDecl, blocks []tagBlock, typesByName map[string]*ast.TypeSpec, typesPkg *types.Package, pos token.Position) *BroadcastDoc {
+	bcastDoc := &BroadcastDoc{
+		exported:    fn.Name.IsExported(),
+		typesByName: typesByName,
+		typesPkg:    typesPkg,
+		pos:         pos,
+	}
+
+	if b := firstBlock(blocks, "broadcast"); b != nil {
+		bcastDoc.Name = b.arg
+	}
+	if b := firstBlock(blocks, "description"); b != nil {
+		bcastDoc.Description = joinTagText(b)
+	}
+
+	bcastDoc.Samples = buildSamples(blocks)
+	_, bcastDoc.respTypeName = requestResponseTypeNames(fn)
+
+	return bcastDoc
+}
+
+// buildSamples collects every @sampleBody/@sampleResponse block, in the
+// order they appeared in the comment.
+func buildSamples(blocks []tagBlock) []DocSample {
+	var samples []DocSample
+	for _, b := range blocks {
+		var st sampleType
+		switch b.tag {
+		case "sampleBody":
+			st = sampleTypeBody
+		case "sampleResponse":
+			st = sampleTypeResponse
+		default:
+			continue
+		}
+
+		samples = append(samples, DocSample{
+			Type:     st,
+			Text:     strings.TrimSpace(b.arg + "\n" + b.body),
+			Language: b.lang,
+			Code:     b.code,
+		})
+	}
+	return samples
+}
+
+// joinTagText combines a tag's same-line argument with its following body
+// lines into one trimmed block of text.
+func joinTagText(b *tagBlock) string {
+	return strings.TrimSpace(strings.TrimSpace(b.arg) + "\n" + b.body)
+}
+
+// splitArg splits a tag argument like "id the id of the user" into its
+// first word and the remainder, as used by @pathArg.
+func splitArg(arg string) (name, description string) {
+	parts := strings.SplitN(arg, " ", 2)
+	name = parts[0]
+	if len(parts) > 1 {
+		description = strings.TrimSpace(parts[1])
+	}
+	return name, description
+}
@@ -0,0 +1,103 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"text/template"
+)
+
+// htmlRenderer writes the static HTML site: one page per package plus an
+// index, alongside the prism.js/prism.css/style.css assets.
+type htmlRenderer struct{}
+
+// Render implements Renderer.
+func (r *htmlRenderer) Render(pkgDocs map[string]*PackageDoc, allPkgNames []string, baseOutputDir string) error {
+	tmpl, err := template.ParseFiles("package_template.html")
+	if err != nil {
+		return err
+	}
+
+	outputDir := filepath.Join(baseOutputDir, "docs")
+	if err := os.MkdirAll(outputDir, 0755); err != nil {
+		return fmt.Errorf("error creating output dir: %v", err)
+	}
+
+	for pkgName, pkgDoc := range pkgDocs {
+		fileName := filepath.Join(outputDir, pkgName+".html")
+		file, err := os.Create(fileName)
+		if err != nil {
+			return err
+		}
+		defer file.Close()
+		err = tmpl.Execute(file, map[string]interface{}{
+			"PackageNames": allPkgNames,
+			"PackageDocs":  pkgDoc,
+		})
+		if err != nil {
+			return fmt.Errorf("template error: %v", err)
+		}
+	}
+
+	// create the index file
+	tmpl, err = template.ParseFiles("index_template.html")
+	if err != nil {
+		return err
+	}
+
+	fileName := filepath.Join(outputDir, "index.html")
+	file, err := os.Create(fileName)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	err = tmpl.Execute(file, map[string]interface{}{
+		"PackageNames": allPkgNames,
+	})
+	if err != nil {
+		return fmt.Errorf("template error: %v", err)
+	}
+
+	// copy the stylesheet and js files
+	if err := copyFile("prism.css", filepath.Join(outputDir, "prism.css")); err != nil {
+		return fmt.Errorf("error copying prism.css: %v", err)
+	}
+	if err := copyFile("prism.js", filepath.Join(outputDir, "prism.js")); err != nil {
+		return fmt.Errorf("error copying prism.js: %v", err)
+	}
+	if err := copyFile("style.css", filepath.Join(outputDir, "style.css")); err != nil {
+		return fmt.Errorf("error copying style.css: %v", err)
+	}
+	if err := copyFile("search.js", filepath.Join(outputDir, "search.js")); err != nil {
+		return fmt.Errorf("error copying search.js: %v", err)
+	}
+
+	searchIndex := buildSearchIndex(pkgDocs, allPkgNames)
+	if err := writeJSONFile(filepath.Join(outputDir, "search-index.json"), searchIndex); err != nil {
+		return fmt.Errorf("error writing search-index.json: %v", err)
+	}
+
+	return nil
+}
+
+func copyFile(src, dst string) error {
+	srcFile, err := os.Open(src)
+	if err != nil {
+		return fmt.Errorf("error opening src: %v", err)
+	}
+	defer srcFile.Close()
+
+	dstFile, err := os.Create(dst)
+	if err != nil {
+		return fmt.Errorf("error opening dst: %v", err)
+	}
+	defer dstFile.Close()
+
+	_, err = io.Copy(dstFile, srcFile)
+	if err != nil {
+		return fmt.Errorf("error copying data: %v", err)
+	}
+
+	return nil
+}
@@ -2,17 +2,16 @@ package main
 
 import (
 	"encoding/json"
+	"flag"
 	"fmt"
+	"go/ast"
 	"go/parser"
 	"go/token"
-	"io"
+	"go/types"
 	"log"
 	"os"
-	"path/filepath"
-	"regexp"
 	"sort"
 	"strings"
-	"text/template"
 	"unicode"
 
 	"github.com/russross/blackfriday"
@@ -63,6 +62,31 @@ type RESTDoc struct {
 	PathArgs    []EndpointArgument `json:"path_arguments,omitempty"`
 	QueryArgs   []EndpointArgument `json:"query_argument,omitempty"`
 	Purpose     string             `json:"purpose,omitempty"`
+
+	exported     bool
+	reqTypeName  string
+	respTypeName string
+	typesByName  map[string]*ast.TypeSpec
+	typesPkg     *types.Package
+	pos          token.Position
+}
+
+// IsExported reports whether the declaration this doc is bound to is an
+// exported identifier.
+func (rd RESTDoc) IsExported() bool {
+	return rd.exported
+}
+
+// RequestFields returns the fields of the request type inferred from the
+// bound declaration's signature, or nil if none could be resolved.
+func (rd RESTDoc) RequestFields() []StructField {
+	return resolveStructFields(rd.reqTypeName, rd.typesPkg, rd.typesByName)
+}
+
+// ResponseFields returns the fields of the response type inferred from the
+// bound declaration's signature, or nil if none could be resolved.
+func (rd RESTDoc) ResponseFields() []StructField {
+	return resolveStructFields(rd.respTypeName, rd.typesPkg, rd.typesByName)
 }
 
 // HTMLDescription converts the description from markdown to html
@@ -101,279 +125,58 @@ type PackageDoc struct {
 	BroadcastDocs []*BroadcastDoc
 }
 
-var endpointRE = regexp.MustCompile(`@endpoint +(\S+)`)
-var methodRE = regexp.MustCompile("@method +(DELETE|GET|POST|PUT)")
-var commandRE = regexp.MustCompile("@command +([^@]*)")
-var sampleRE = regexp.MustCompile(`(@sampleBody|@sampleResponse)[^@]*`)
-var samplePartsRE = regexp.MustCompile("(@sampleBody|@sampleResponse) *\\n((.|\\n)+)?``` *(.*)((.|\\n)+)(?:```)")
-var descriptionRE = regexp.MustCompile(`@description +([^@]+)`)
-var broadcastRE = regexp.MustCompile(`@broadcast +(\w+)`)
-var packageRE = regexp.MustCompile(`@package +(\S+)`)
-var pathArgRE = regexp.MustCompile(`@pathArg +(\w*) +(.*)`)
-var purposeRE = regexp.MustCompile(`@purpose +(.+)`)
-
 func main() {
-	if len(os.Args) < 2 {
-		log.Fatal("You need to specify a path to scan")
-	}
-	if len(os.Args) < 3 {
-		log.Fatal("You need to specify a path for the output directory")
-	}
-	pkgs, err := parser.ParseDir(token.NewFileSet(), os.Args[1], nil, parser.ParseComments)
-	if err != nil {
-		log.Fatal(err)
-	}
-
-	pkgDocs := make(map[string]*PackageDoc)
-
-	for _, pkg := range pkgs {
-		for _, srcFile := range pkg.Files {
-			for _, cg := range srcFile.Comments {
-				for _, cmnt := range cg.List {
-					comment := cmnt.Text
-					packageMatches := packageRE.FindStringSubmatch(comment)
-					if packageMatches == nil {
-						continue
-					}
-					if rd := parseRESTDoc(comment); rd != nil {
-						pkgDoc := pkgDocs[rd.PackageName]
-						if pkgDoc == nil {
-							pkgDoc = &PackageDoc{Name: rd.PackageName}
-							pkgDocs[pkgDoc.Name] = pkgDoc
-						}
-						pkgDoc.RESTDocs = append(pkgDoc.RESTDocs, rd)
-						continue
-					}
-					if rpcDoc := parseRPCDoc(comment); rpcDoc != nil {
-						pkgDoc := pkgDocs[rpcDoc.PackageName]
-						if pkgDoc == nil {
-							pkgDoc = &PackageDoc{Name: rpcDoc.PackageName}
-							pkgDocs[pkgDoc.Name] = pkgDoc
-						}
-						pkgDoc.RPCDocs = append(pkgDoc.RPCDocs, rpcDoc)
-						continue
-					}
-					if bcastDoc := parseBroadcastDoc(comment); bcastDoc != nil {
-						pkgDoc := pkgDocs[bcastDoc.PackageName]
-						if pkgDoc == nil {
-							pkgDoc = &PackageDoc{Name: bcastDoc.PackageName}
-							pkgDocs[pkgDoc.Name] = pkgDoc
-						}
-						pkgDoc.BroadcastDocs = append(pkgDoc.BroadcastDocs, bcastDoc)
-						continue
-					}
-				}
-			}
-		}
+	if len(os.Args) > 1 && os.Args[1] == "serve" {
+		runServe(os.Args[2:])
+		return
 	}
 
-	// sort the commands and broadcasts for each package
-	for _, pkgDoc := range pkgDocs {
-		sortableRPCs := byRPCCommand(pkgDoc.RPCDocs)
-		sort.Sort(sortableRPCs)
+	format := flag.String("format", "html", "output format to generate: html, openapi, or all")
+	unexported := flag.Bool("unexported", false, "include docs bound to unexported declarations")
+	strict := flag.Bool("strict", false, "fail if a sample doesn't match its inferred request/response type")
+	flag.Parse()
 
-		sortableBCs := byBroadcastName(pkgDoc.BroadcastDocs)
-		sort.Sort(sortableBCs)
-	}
-
-	var allPkgNames []string
-	for pkgName := range pkgDocs {
-		allPkgNames = append(allPkgNames, pkgName)
-	}
-	sort.Strings(allPkgNames)
-	tmpl, err := template.ParseFiles("package_template.html")
-	if err != nil {
-		log.Fatal(err)
-	}
-
-	outputDir := filepath.Join(os.Args[2], "docs")
-	err = os.MkdirAll(outputDir, 0755)
-	if err != nil {
-		log.Fatalf("Error creating output dir: %v", err)
+	if flag.NArg() < 1 {
+		log.Fatal("You need to specify a path to scan")
 	}
-
-	for pkgName, pkgDoc := range pkgDocs {
-		fileName := filepath.Join(outputDir, pkgName+".html")
-		file, err := os.Create(fileName)
-		if err != nil {
-			log.Fatal(err)
-		}
-		defer file.Close()
-		err = tmpl.Execute(file, map[string]interface{}{
-			"PackageNames": allPkgNames,
-			"PackageDocs":  pkgDoc,
-		})
-		if err != nil {
-			log.Fatalf("Template error: %v", err)
-		}
+	if flag.NArg() < 2 {
+		log.Fatal("You need to specify a path for the output directory")
 	}
+	srcDir := flag.Arg(0)
+	baseOutputDir := flag.Arg(1)
 
-	// create the index file
-	tmpl, err = template.ParseFiles("index_template.html")
+	renderers, err := renderersForFormat(*format)
 	if err != nil {
 		log.Fatal(err)
 	}
 
-	fileName := filepath.Join(outputDir, "index.html")
-	file, err := os.Create(fileName)
+	fset := token.NewFileSet()
+	pkgs, err := parser.ParseDir(fset, srcDir, nil, parser.ParseComments)
 	if err != nil {
 		log.Fatal(err)
 	}
-	defer file.Close()
-	err = tmpl.Execute(file, map[string]interface{}{
-		"PackageNames": allPkgNames,
-	})
-	if err != nil {
-		log.Fatalf("Template error: %v", err)
-	}
 
-	// copy the stylesheet and js files
-	err = copyFile("prism.css", filepath.Join(outputDir, "prism.css"))
-	if err != nil {
-		log.Fatalf("Error copying prism.css: %v", err)
-	}
-	err = copyFile("prism.js", filepath.Join(outputDir, "prism.js"))
-	if err != nil {
-		log.Fatalf("Error copying prism.js: %v", err)
-	}
-	err = copyFile("style.css", filepath.Join(outputDir, "style.css"))
-	if err != nil {
-		log.Fatalf("Error copying docs.css: %v", err)
-	}
-}
+	pkgDocs := buildPackageDocs(fset, pkgs, *unexported)
 
-func copyFile(src, dst string) error {
-	srcFile, err := os.Open(src)
-	if err != nil {
-		return fmt.Errorf("error opening src: %v", err)
-	}
-	defer srcFile.Close()
-
-	dstFile, err := os.Create(dst)
-	if err != nil {
-		return fmt.Errorf("error opening dst: %v", err)
-	}
-	defer dstFile.Close()
-
-	_, err = io.Copy(dstFile, srcFile)
-	if err != nil {
-		return fmt.Errorf("error copying data: %v", err)
-	}
-
-	return nil
-}
-
-func parseRESTDoc(comment string) *RESTDoc {
-	pkgs := packageRE.FindStringSubmatch(comment)
-	if len(pkgs) < 2 {
-		return nil
-	}
-	endpoints := endpointRE.FindStringSubmatch(comment)
-	if len(endpoints) < 2 {
-		return nil
-	}
-
-	doc := RESTDoc{PackageName: pkgs[1], Endpoint: endpoints[1]}
-
-	// default method is GET
-	methods := methodRE.FindStringSubmatch(comment)
-	if methods == nil {
-		doc.Method = "GET"
-	} else {
-		doc.Method = methods[1]
-	}
-
-	doc.Samples = parseDocSamples(comment)
-
-	descriptionMatches := descriptionRE.FindStringSubmatch(comment)
-	if len(descriptionMatches) > 1 {
-		doc.Description = strings.TrimSpace(descriptionMatches[1])
-	}
-
-	// look for path arguments
-	pathArgs := pathArgRE.FindAllStringSubmatch(comment, -1)
-	if len(descriptionMatches) > 0 {
-		for _, paParts := range pathArgs {
-			pa := EndpointArgument{
-				Name:        paParts[1],
-				Description: paParts[2],
-			}
-			doc.PathArgs = append(doc.PathArgs, pa)
+	if *strict {
+		violations := validateDocSamples(pkgDocs)
+		for _, v := range violations {
+			fmt.Fprintln(os.Stderr, v)
+		}
+		if len(violations) > 0 {
+			os.Exit(1)
 		}
 	}
 
-	purposeMatches := purposeRE.FindStringSubmatch(comment)
-	if purposeMatches != nil {
-		doc.Purpose = purposeMatches[1]
-	}
-
-	return &doc
-}
-
-func parseRPCDoc(comment string) *RPCDoc {
-	pkgs := packageRE.FindStringSubmatch(comment)
-	if len(pkgs) < 2 {
-		return nil
-	}
-	commandMatches := commandRE.FindStringSubmatch(comment)
-	if commandMatches == nil {
-		return nil
-	}
-
-	doc := RPCDoc{PackageName: pkgs[1], Command: strings.TrimSpace(commandMatches[1])}
-
-	doc.Samples = parseDocSamples(comment)
-
-	descriptionMatches := descriptionRE.FindStringSubmatch(comment)
-	if descriptionMatches != nil {
-		doc.Description = descriptionMatches[1]
-	}
-
-	return &doc
-}
-
-func parseBroadcastDoc(comment string) *BroadcastDoc {
-	packageMatches := packageRE.FindStringSubmatch(comment)
-	if packageMatches == nil {
-		return nil
-	}
-	bcastMatches := broadcastRE.FindStringSubmatch(comment)
-	if bcastMatches == nil {
-		return nil
-	}
-
-	doc := BroadcastDoc{PackageName: packageMatches[1], Name: bcastMatches[1]}
-
-	doc.Samples = parseDocSamples(comment)
-
-	descriptionMatches := descriptionRE.FindStringSubmatch(comment)
-	if descriptionMatches != nil {
-		doc.Description = descriptionMatches[1]
+	var allPkgNames []string
+	for pkgName := range pkgDocs {
+		allPkgNames = append(allPkgNames, pkgName)
 	}
+	sort.Strings(allPkgNames)
 
-	return &doc
-}
-
-func parseDocSamples(comment string) []DocSample {
-	var samples []DocSample
-
-	sampleMatches := sampleRE.FindAllString(comment, -1)
-	for _, sample := range sampleMatches {
-		parts := samplePartsRE.FindStringSubmatch(sample)
-		if len(parts) == 0 {
-			continue
+	for _, renderer := range renderers {
+		if err := renderer.Render(pkgDocs, allPkgNames, baseOutputDir); err != nil {
+			log.Fatalf("Render error: %v", err)
 		}
-		ds := DocSample{}
-		if parts[1] == "@sampleBody" {
-			ds.Type = sampleTypeBody
-		} else if parts[1] == "@sampleResponse" {
-			ds.Type = sampleTypeResponse
-		}
-		ds.Text = parts[2]
-		ds.Language = parts[4]
-		ds.Code = strings.TrimSpace(parts[5])
-		samples = append(samples, ds)
 	}
-
-	return samples
 }
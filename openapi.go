@@ -0,0 +1,173 @@
+package main
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// openAPIRenderer emits an OpenAPI 3.0 specification describing the
+// collected RESTDocs.
+type openAPIRenderer struct{}
+
+type openAPISpec struct {
+	OpenAPI string                     `json:"openapi"`
+	Info    openAPIInfo                `json:"info"`
+	Paths   map[string]openAPIPathItem `json:"paths"`
+}
+
+type openAPIInfo struct {
+	Title   string `json:"title"`
+	Version string `json:"version"`
+}
+
+// openAPIPathItem maps an HTTP method (lowercase) to the operation
+// documented for it.
+type openAPIPathItem map[string]openAPIOperation
+
+type openAPIOperation struct {
+	Summary     string                     `json:"summary,omitempty"`
+	Description string                     `json:"description,omitempty"`
+	Parameters  []openAPIParameter         `json:"parameters,omitempty"`
+	RequestBody *openAPIRequestBody        `json:"requestBody,omitempty"`
+	Responses   map[string]openAPIResponse `json:"responses"`
+}
+
+type openAPIParameter struct {
+	Name        string        `json:"name"`
+	In          string        `json:"in"`
+	Description string        `json:"description,omitempty"`
+	Required    bool          `json:"required,omitempty"`
+	Schema      openAPISchema `json:"schema"`
+}
+
+type openAPISchema struct {
+	Type string `json:"type"`
+}
+
+type openAPIRequestBody struct {
+	Content map[string]openAPIMediaType `json:"content"`
+}
+
+type openAPIResponse struct {
+	Description string                      `json:"description"`
+	Content     map[string]openAPIMediaType `json:"content,omitempty"`
+}
+
+type openAPIMediaType struct {
+	Examples map[string]openAPIExample `json:"examples,omitempty"`
+}
+
+type openAPIExample struct {
+	Summary string      `json:"summary,omitempty"`
+	Value   interface{} `json:"value,omitempty"`
+}
+
+// Render implements Renderer.
+func (r *openAPIRenderer) Render(pkgDocs map[string]*PackageDoc, allPkgNames []string, outputDir string) error {
+	paths := make(map[string]openAPIPathItem)
+	for _, pkgName := range allPkgNames {
+		for _, rd := range pkgDocs[pkgName].RESTDocs {
+			item, ok := paths[rd.Endpoint]
+			if !ok {
+				item = openAPIPathItem{}
+				paths[rd.Endpoint] = item
+			}
+			item[rd.LowercaseMethod()] = restDocToOperation(rd)
+		}
+	}
+
+	docsDir := filepath.Join(outputDir, "docs")
+	if err := os.MkdirAll(docsDir, 0755); err != nil {
+		return err
+	}
+
+	spec := openAPISpec{
+		OpenAPI: "3.0.0",
+		Info:    openAPIInfo{Title: "docbrown", Version: "1.0.0"},
+		Paths:   paths,
+	}
+	return writeJSONFile(filepath.Join(docsDir, "openapi.json"), spec)
+}
+
+func restDocToOperation(rd *RESTDoc) openAPIOperation {
+	op := openAPIOperation{
+		Summary:     rd.Purpose,
+		Description: rd.Description,
+		Responses:   map[string]openAPIResponse{},
+	}
+
+	for _, pa := range rd.PathArgs {
+		op.Parameters = append(op.Parameters, openAPIParameter{
+			Name:        pa.Name,
+			In:          "path",
+			Description: pa.Description,
+			Required:    true,
+			Schema:      openAPISchema{Type: "string"},
+		})
+	}
+	for _, qa := range rd.QueryArgs {
+		op.Parameters = append(op.Parameters, openAPIParameter{
+			Name:        qa.Name,
+			In:          "query",
+			Description: qa.Description,
+			Schema:      openAPISchema{Type: "string"},
+		})
+	}
+
+	bodyExamples := map[string]openAPIExample{}
+	responseExamples := map[string]openAPIExample{}
+	for _, sample := range rd.Samples {
+		example := openAPIExample{Summary: sample.Text, Value: sampleValue(sample)}
+		language := sample.Language
+		if language == "" {
+			language = "default"
+		}
+		switch sample.Type {
+		case sampleTypeBody:
+			bodyExamples[language] = example
+		case sampleTypeResponse:
+			responseExamples[language] = example
+		}
+	}
+
+	if len(bodyExamples) > 0 {
+		op.RequestBody = &openAPIRequestBody{
+			Content: map[string]openAPIMediaType{
+				"application/json": {Examples: bodyExamples},
+			},
+		}
+	}
+
+	description := "OK"
+	var content map[string]openAPIMediaType
+	if len(responseExamples) > 0 {
+		content = map[string]openAPIMediaType{
+			"application/json": {Examples: responseExamples},
+		}
+	}
+	op.Responses["200"] = openAPIResponse{Description: description, Content: content}
+
+	return op
+}
+
+// sampleValue unmarshals a sample's code as JSON for use as an OpenAPI
+// example value, falling back to the raw code string when it isn't valid
+// JSON (e.g. it's a curl invocation rather than a body).
+func sampleValue(sample DocSample) interface{} {
+	trimmed := strings.TrimSpace(sample.Code)
+	var v interface{}
+	if err := json.Unmarshal([]byte(trimmed), &v); err != nil {
+		return trimmed
+	}
+	return v
+}
+
+func writeJSONFile(path string, v interface{}) error {
+	buf, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, buf, 0644)
+}
@@ -0,0 +1,35 @@
+package main
+
+// Renderer turns a set of parsed PackageDocs into output on disk. Each
+// supported -format value maps to one Renderer implementation, so adding a
+// new output (Postman collection, AsyncAPI for BroadcastDocs, ...) only
+// means writing a new Renderer and wiring it into renderersForFormat -- the
+// parser and the PackageDoc/RESTDoc/RPCDoc/BroadcastDoc types never change.
+type Renderer interface {
+	// Render writes its representation of pkgDocs into outputDir, creating
+	// the directory structure it needs as it goes.
+	Render(pkgDocs map[string]*PackageDoc, allPkgNames []string, outputDir string) error
+}
+
+// renderersForFormat returns the Renderers that should run for the given
+// -format flag value. "all" runs every known renderer.
+func renderersForFormat(format string) ([]Renderer, error) {
+	switch format {
+	case "", "html":
+		return []Renderer{&htmlRenderer{}}, nil
+	case "openapi":
+		return []Renderer{&openAPIRenderer{}}, nil
+	case "all":
+		return []Renderer{&htmlRenderer{}, &openAPIRenderer{}}, nil
+	default:
+		return nil, unknownFormatError{format}
+	}
+}
+
+type unknownFormatError struct {
+	format string
+}
+
+func (e unknownFormatError) Error() string {
+	return "unknown -format value: " + e.format
+}
@@ -3,6 +3,9 @@ package main
 import (
 	"encoding/json"
 	"fmt"
+	"go/ast"
+	"go/token"
+	"go/types"
 	"strings"
 
 	"github.com/russross/blackfriday"
@@ -14,6 +17,31 @@ type RPCDoc struct {
 	Description string      `json:"description,omitempty"`
 	Samples     []DocSample `json:"samples,omitempty"`
 	PackageName string      `json:"package_name"`
+
+	exported     bool
+	reqTypeName  string
+	respTypeName string
+	typesByName  map[string]*ast.TypeSpec
+	typesPkg     *types.Package
+	pos          token.Position
+}
+
+// IsExported reports whether the declaration this doc is bound to is an
+// exported identifier.
+func (rd RPCDoc) IsExported() bool {
+	return rd.exported
+}
+
+// RequestFields returns the fields of the request type inferred from the
+// bound declaration's signature, or nil if none could be resolved.
+func (rd RPCDoc) RequestFields() []StructField {
+	return resolveStructFields(rd.reqTypeName, rd.typesPkg, rd.typesByName)
+}
+
+// ResponseFields returns the fields of the response type inferred from the
+// bound declaration's signature, or nil if none could be resolved.
+func (rd RPCDoc) ResponseFields() []StructField {
+	return resolveStructFields(rd.respTypeName, rd.typesPkg, rd.typesByName)
 }
 
 // HTMLDescription converts the description from markdown to html
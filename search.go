@@ -0,0 +1,190 @@
+package main
+
+import (
+	"regexp"
+	"sort"
+	"strings"
+)
+
+const (
+	fieldWeightName        = 10
+	fieldWeightDescription = 5
+	fieldWeightSample      = 1
+)
+
+// searchDocRef identifies the doc a posting belongs to, without embedding
+// the full RESTDoc/RPCDoc/BroadcastDoc (which would duplicate the whole
+// corpus once per token).
+type searchDocRef struct {
+	PackageName string `json:"package"`
+	Kind        string `json:"kind"` // "rest", "rpc", or "broadcast"
+	Label       string `json:"label"`
+	HTMLID      string `json:"html_id"`
+}
+
+type searchPosting struct {
+	Ref    searchDocRef `json:"ref"`
+	Weight int          `json:"weight"`
+}
+
+// SearchIndex is an inverted index over the parsed docs: token -> postings.
+// It's emitted as search-index.json for search.js to query client-side,
+// and reused as-is by the serve mode's /search handler.
+type SearchIndex struct {
+	Tokens map[string][]searchPosting `json:"tokens"`
+}
+
+type searchResult struct {
+	Ref   searchDocRef `json:"ref"`
+	Score int          `json:"score"`
+}
+
+// buildSearchIndex tokenizes every RESTDoc/RPCDoc/BroadcastDoc's name,
+// purpose, description and sample code, weighting matches by field so an
+// endpoint/command match outranks a description match, which outranks a
+// sample-code match.
+func buildSearchIndex(pkgDocs map[string]*PackageDoc, allPkgNames []string) *SearchIndex {
+	idx := &SearchIndex{Tokens: make(map[string][]searchPosting)}
+
+	add := func(ref searchDocRef, text string, weight int) {
+		for _, token := range tokenize(text) {
+			idx.Tokens[token] = append(idx.Tokens[token], searchPosting{Ref: ref, Weight: weight})
+		}
+	}
+
+	for _, pkgName := range allPkgNames {
+		pkgDoc := pkgDocs[pkgName]
+
+		for _, rd := range pkgDoc.RESTDocs {
+			ref := searchDocRef{PackageName: pkgName, Kind: "rest", Label: rd.Endpoint, HTMLID: rd.HTMLID()}
+			add(ref, rd.Endpoint, fieldWeightName)
+			add(ref, rd.Purpose, fieldWeightName)
+			add(ref, stripTags(rd.HTMLDescription()), fieldWeightDescription)
+			for _, sample := range rd.Samples {
+				add(ref, sample.Code, fieldWeightSample)
+			}
+		}
+		for _, rpcDoc := range pkgDoc.RPCDocs {
+			ref := searchDocRef{PackageName: pkgName, Kind: "rpc", Label: rpcDoc.Command, HTMLID: rpcDoc.HTMLID()}
+			add(ref, rpcDoc.Command, fieldWeightName)
+			add(ref, stripTags(rpcDoc.HTMLDescription()), fieldWeightDescription)
+			for _, sample := range rpcDoc.Samples {
+				add(ref, sample.Code, fieldWeightSample)
+			}
+		}
+		for _, bcastDoc := range pkgDoc.BroadcastDocs {
+			ref := searchDocRef{PackageName: pkgName, Kind: "broadcast", Label: bcastDoc.Name, HTMLID: bcastDoc.HTMLID()}
+			add(ref, bcastDoc.Name, fieldWeightName)
+			add(ref, stripTags(bcastDoc.HTMLDescription()), fieldWeightDescription)
+			for _, sample := range bcastDoc.Samples {
+				add(ref, sample.Code, fieldWeightSample)
+			}
+		}
+	}
+
+	return idx
+}
+
+// Query returns every doc matching all terms in q (AND of terms), scored by
+// summing the weight of each term's matching postings. A term matches a
+// token by prefix, so "auth" matches "authenticate".
+func (idx *SearchIndex) Query(q string) []searchResult {
+	terms := uniqueTokens(tokenize(q))
+	if len(terms) == 0 {
+		return nil
+	}
+
+	scores := map[searchDocRef]int{}
+	matched := map[searchDocRef]map[string]bool{}
+
+	for token, postings := range idx.Tokens {
+		for _, term := range terms {
+			if !strings.HasPrefix(token, term) {
+				continue
+			}
+			for _, posting := range postings {
+				scores[posting.Ref] += posting.Weight
+				if matched[posting.Ref] == nil {
+					matched[posting.Ref] = map[string]bool{}
+				}
+				matched[posting.Ref][term] = true
+			}
+		}
+	}
+
+	var results []searchResult
+	for ref, termsMatched := range matched {
+		if len(termsMatched) != len(terms) {
+			continue
+		}
+		results = append(results, searchResult{Ref: ref, Score: scores[ref]})
+	}
+
+	sort.Slice(results, func(i, j int) bool { return results[i].Score > results[j].Score })
+
+	return results
+}
+
+var tokenRE = regexp.MustCompile(`[A-Za-z0-9]+`)
+
+var searchStopwords = map[string]bool{
+	"a": true, "an": true, "the": true, "and": true, "or": true, "of": true,
+	"to": true, "in": true, "is": true, "it": true, "for": true, "on": true,
+	"with": true, "this": true, "that": true, "be": true, "are": true,
+	"as": true, "by": true, "at": true, "from": true,
+}
+
+// tokenize lowercases text, splits it into words, drops stopwords, and
+// stems what's left.
+func tokenize(text string) []string {
+	var tokens []string
+	for _, word := range tokenRE.FindAllString(strings.ToLower(text), -1) {
+		if searchStopwords[word] {
+			continue
+		}
+		tokens = append(tokens, stem(word))
+	}
+	return tokens
+}
+
+// stem applies a small set of suffix-stripping rules -- not a full Porter
+// stemmer, just enough to fold "user"/"users", "query"/"queries",
+// "updated"/"updating", and similar variants onto the same token. Not every
+// pair folds: "samples" stems to "sampl" while "sample" stays "sample".
+func stem(word string) string {
+	switch {
+	case strings.HasSuffix(word, "ies") && len(word) > 4:
+		return word[:len(word)-3] + "y"
+	case strings.HasSuffix(word, "ing") && len(word) > 5:
+		return word[:len(word)-3]
+	case strings.HasSuffix(word, "ed") && len(word) > 4:
+		return word[:len(word)-2]
+	case strings.HasSuffix(word, "es") && len(word) > 4:
+		return word[:len(word)-2]
+	case strings.HasSuffix(word, "s") && !strings.HasSuffix(word, "ss") && len(word) > 3:
+		return word[:len(word)-1]
+	default:
+		return word
+	}
+}
+
+func uniqueTokens(tokens []string) []string {
+	seen := map[string]bool{}
+	var unique []string
+	for _, t := range tokens {
+		if seen[t] {
+			continue
+		}
+		seen[t] = true
+		unique = append(unique, t)
+	}
+	return unique
+}
+
+var htmlTagRE = regexp.MustCompile(`<[^>]*>`)
+
+// stripTags removes HTML markup so rendered markdown can be tokenized as
+// plain text.
+func stripTags(html string) string {
+	return htmlTagRE.ReplaceAllString(html, " ")
+}
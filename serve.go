@@ -0,0 +1,229 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"go/parser"
+	"go/token"
+	"io/fs"
+	"log"
+	"net/http"
+	"path/filepath"
+	"sort"
+	"sync"
+	"text/template"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+)
+
+// reloadDebounce is how long watchAndReload waits for fsnotify events to go
+// quiet before reloading. Editors, `go build`, and git all tend to fire
+// several fs events per logical save, and each reload re-parses the source
+// tree and runs a full go/types check, so reacting to every event turns one
+// save into several redundant, multi-second rebuilds.
+const reloadDebounce = 300 * time.Millisecond
+
+// Corpus is the live, re-parsed state behind serve mode: the ServeMux built
+// from the current docs and templates, whose handlers capture both.
+// Requests read corpus.mux under an RLock while a re-parse triggered by
+// fsnotify swaps in a freshly built one under a Lock, the same
+// read-heavy/write-rare split godoc's RWValue uses for its package index.
+type Corpus struct {
+	mu  sync.RWMutex
+	mux *http.ServeMux
+}
+
+// ServeHTTP implements http.Handler by delegating to the current mux.
+func (c *Corpus) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	c.mu.RLock()
+	mux := c.mux
+	c.mu.RUnlock()
+	mux.ServeHTTP(w, r)
+}
+
+// reload re-parses srcDir and the HTML templates, then atomically swaps in
+// the ServeMux built from the result.
+func (c *Corpus) reload(srcDir string) error {
+	fset := token.NewFileSet()
+	pkgs, err := parser.ParseDir(fset, srcDir, nil, parser.ParseComments)
+	if err != nil {
+		return err
+	}
+	pkgDocs := buildPackageDocs(fset, pkgs, false)
+
+	var allPkgNames []string
+	for name := range pkgDocs {
+		allPkgNames = append(allPkgNames, name)
+	}
+	sort.Strings(allPkgNames)
+
+	pkgTmpl, err := template.ParseFiles("package_template.html")
+	if err != nil {
+		return err
+	}
+	idxTmpl, err := template.ParseFiles("index_template.html")
+	if err != nil {
+		return err
+	}
+
+	mux := buildServeMux(pkgDocs, allPkgNames, pkgTmpl, idxTmpl)
+
+	c.mu.Lock()
+	c.mux = mux
+	c.mu.Unlock()
+
+	return nil
+}
+
+// buildServeMux mounts /pkg/<name>, /_json/<name>, /index and the static
+// asset handlers for one snapshot of pkgDocs.
+func buildServeMux(pkgDocs map[string]*PackageDoc, allPkgNames []string, pkgTmpl, idxTmpl *template.Template) *http.ServeMux {
+	mux := http.NewServeMux()
+
+	for _, name := range allPkgNames {
+		pkgDoc := pkgDocs[name]
+		mux.HandleFunc("/pkg/"+name, func(w http.ResponseWriter, r *http.Request) {
+			err := pkgTmpl.Execute(w, map[string]interface{}{
+				"PackageNames": allPkgNames,
+				"PackageDocs":  pkgDoc,
+			})
+			if err != nil {
+				http.Error(w, err.Error(), http.StatusInternalServerError)
+			}
+		})
+		mux.HandleFunc("/_json/"+name, func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Type", "application/json")
+			if err := json.NewEncoder(w).Encode(pkgDoc); err != nil {
+				http.Error(w, err.Error(), http.StatusInternalServerError)
+			}
+		})
+	}
+
+	searchIndex := buildSearchIndex(pkgDocs, allPkgNames)
+	mux.HandleFunc("/search", func(w http.ResponseWriter, r *http.Request) {
+		results := searchIndex.Query(r.URL.Query().Get("q"))
+		w.Header().Set("Content-Type", "application/json")
+		if err := json.NewEncoder(w).Encode(results); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+		}
+	})
+
+	mux.HandleFunc("/index", func(w http.ResponseWriter, r *http.Request) {
+		err := idxTmpl.Execute(w, map[string]interface{}{"PackageNames": allPkgNames})
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+		}
+	})
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, "/index", http.StatusFound)
+	})
+
+	for _, name := range []string{"prism.css", "prism.js", "style.css"} {
+		name := name
+		mux.HandleFunc("/"+name, func(w http.ResponseWriter, r *http.Request) {
+			http.ServeFile(w, r, name)
+		})
+	}
+
+	return mux
+}
+
+// runServe implements `docbrown serve -addr :6060 -watch <srcdir>`: an
+// in-process HTTP server that re-parses srcDir and the templates whenever
+// fsnotify reports a change, instead of writing static files to disk.
+func runServe(args []string) {
+	flagSet := flag.NewFlagSet("serve", flag.ExitOnError)
+	addr := flagSet.String("addr", ":6060", "address to listen on")
+	watchDir := flagSet.String("watch", ".", "source directory to parse and watch for changes")
+	flagSet.Parse(args)
+
+	corpus := &Corpus{}
+	if err := corpus.reload(*watchDir); err != nil {
+		log.Fatalf("Error parsing %s: %v", *watchDir, err)
+	}
+
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		log.Fatalf("Error creating watcher: %v", err)
+	}
+	defer watcher.Close()
+
+	if err := watchDirTree(watcher, *watchDir); err != nil {
+		log.Printf("Warning: could not walk %s: %v", *watchDir, err)
+	}
+	for _, path := range []string{"package_template.html", "index_template.html"} {
+		if err := watcher.Add(path); err != nil {
+			log.Printf("Warning: could not watch %s: %v", path, err)
+		}
+	}
+
+	go watchAndReload(corpus, watcher, *watchDir)
+
+	log.Printf("docbrown serve listening on %s, watching %s", *addr, *watchDir)
+	log.Fatal(http.ListenAndServe(*addr, corpus))
+}
+
+// watchDirTree adds dir and every subdirectory beneath it to watcher.
+// fsnotify only watches the directories it's told about, not their
+// descendants, so this is what makes "watch the source tree" mean the whole
+// tree rather than just its top level.
+func watchDirTree(watcher *fsnotify.Watcher, dir string) error {
+	return filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if !d.IsDir() {
+			return nil
+		}
+		if err := watcher.Add(path); err != nil {
+			log.Printf("Warning: could not watch %s: %v", path, err)
+		}
+		return nil
+	})
+}
+
+// watchAndReload re-parses the corpus once fsnotify events on the watched
+// source tree or templates go quiet for reloadDebounce, coalescing the
+// burst of events one save typically produces into a single reload.
+func watchAndReload(corpus *Corpus, watcher *fsnotify.Watcher, watchDir string) {
+	var debounce *time.Timer
+	var pending string
+
+	for {
+		var fire <-chan time.Time
+		if debounce != nil {
+			fire = debounce.C
+		}
+
+		select {
+		case event, ok := <-watcher.Events:
+			if !ok {
+				return
+			}
+			pending = event.Name
+			if debounce != nil && !debounce.Stop() {
+				<-debounce.C
+			}
+			debounce = time.NewTimer(reloadDebounce)
+
+		case <-fire:
+			debounce = nil
+			log.Printf("Change detected (%s), reloading docs", pending)
+			if err := corpus.reload(watchDir); err != nil {
+				log.Printf("Error reloading docs: %v", err)
+			}
+			// Re-walk in case the change created a new subdirectory that
+			// needs its own watch added.
+			if err := watchDirTree(watcher, watchDir); err != nil {
+				log.Printf("Warning: could not walk %s: %v", watchDir, err)
+			}
+
+		case err, ok := <-watcher.Errors:
+			if !ok {
+				return
+			}
+			log.Printf("Watcher error: %v", err)
+		}
+	}
+}
@@ -0,0 +1,140 @@
+package main
+
+import "strings"
+
+// tagBlock is a single @tag section extracted from a doc comment: the tag
+// name, any argument on the same line as the tag, free-form body text that
+// followed it, and the contents of a fenced code block if one was present.
+type tagBlock struct {
+	tag  string
+	arg  string
+	body string
+	lang string
+	code string
+}
+
+// knownTags lists the @tag names the scanner recognizes as the start of a
+// new block. Anything else starting with "@" (e.g. an "@" inside prose, or
+// in a description like "ping @someone for help") is left as body text
+// instead of being mistaken for a tag.
+var knownTags = map[string]bool{
+	"package":        true,
+	"endpoint":       true,
+	"method":         true,
+	"command":        true,
+	"broadcast":      true,
+	"pathArg":        true,
+	"purpose":        true,
+	"description":    true,
+	"sampleBody":     true,
+	"sampleResponse": true,
+}
+
+// scanTags tokenizes a doc comment into tagBlocks, one line at a time. A
+// block starts at a line beginning with "@<knownTag>" and continues until
+// the next such line. This replaces the old [^@]+ regexps, which truncated
+// a block's body at the first stray "@" instead of only at a real tag.
+func scanTags(text string) []tagBlock {
+	var blocks []tagBlock
+	var cur *tagBlock
+
+	lines := strings.Split(text, "\n")
+	for i := 0; i < len(lines); i++ {
+		line := lines[i]
+
+		if tag, arg, ok := parseTagLine(line); ok {
+			if cur != nil {
+				blocks = append(blocks, *cur)
+			}
+			cur = &tagBlock{tag: tag, arg: arg}
+			continue
+		}
+
+		if cur == nil {
+			continue
+		}
+
+		if fenceLang, ok := fenceStart(line); ok {
+			code, consumed := readFence(lines[i+1:])
+			cur.lang = fenceLang
+			cur.code = code
+			i += consumed
+			continue
+		}
+
+		if cur.body != "" {
+			cur.body += "\n"
+		}
+		cur.body += line
+	}
+	if cur != nil {
+		blocks = append(blocks, *cur)
+	}
+
+	return blocks
+}
+
+// parseTagLine reports whether line opens a new tag block, e.g.
+// "@endpoint /v1/users" -> ("endpoint", "/v1/users", true).
+func parseTagLine(line string) (tag, arg string, ok bool) {
+	trimmed := strings.TrimSpace(line)
+	if !strings.HasPrefix(trimmed, "@") {
+		return "", "", false
+	}
+
+	rest := trimmed[1:]
+	name := rest
+	if idx := strings.IndexAny(rest, " \t"); idx >= 0 {
+		name, arg = rest[:idx], strings.TrimSpace(rest[idx:])
+	}
+	if !knownTags[name] {
+		return "", "", false
+	}
+
+	return name, arg, true
+}
+
+// fenceStart reports whether line opens a ``` fenced code block, along with
+// the language annotation that followed the fence, if any.
+func fenceStart(line string) (lang string, ok bool) {
+	trimmed := strings.TrimSpace(line)
+	if !strings.HasPrefix(trimmed, "```") {
+		return "", false
+	}
+	return strings.TrimSpace(strings.TrimPrefix(trimmed, "```")), true
+}
+
+// readFence consumes lines up to and including the closing ``` fence,
+// returning the code in between and the number of lines consumed.
+func readFence(lines []string) (code string, consumed int) {
+	var body []string
+	for _, line := range lines {
+		consumed++
+		if strings.HasPrefix(strings.TrimSpace(line), "```") {
+			break
+		}
+		body = append(body, line)
+	}
+	return strings.TrimSpace(strings.Join(body, "\n")), consumed
+}
+
+// firstBlock returns the first block with the given tag, if any.
+func firstBlock(blocks []tagBlock, tag string) *tagBlock {
+	for i := range blocks {
+		if blocks[i].tag == tag {
+			return &blocks[i]
+		}
+	}
+	return nil
+}
+
+// blocksWithTag returns every block with the given tag, in order.
+func blocksWithTag(blocks []tagBlock, tag string) []tagBlock {
+	var matches []tagBlock
+	for _, b := range blocks {
+		if b.tag == tag {
+			matches = append(matches, b)
+		}
+	}
+	return matches
+}
@@ -0,0 +1,80 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseTagLine(t *testing.T) {
+	tests := []struct {
+		name   string
+		line   string
+		tag    string
+		arg    string
+		wantOK bool
+	}{
+		{name: "tag with arg", line: "@endpoint /v1/users", tag: "endpoint", arg: "/v1/users", wantOK: true},
+		{name: "leading whitespace", line: "  @method GET", tag: "method", arg: "GET", wantOK: true},
+		{name: "tag with no arg", line: "@package", tag: "package", arg: "", wantOK: true},
+		{name: "unknown tag", line: "@unknown foo", wantOK: false},
+		{name: "no leading @", line: "endpoint /v1/users", wantOK: false},
+		{name: "stray @ in prose", line: "ping @someone for help", wantOK: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tag, arg, ok := parseTagLine(tt.line)
+			if ok != tt.wantOK {
+				t.Fatalf("parseTagLine(%q) ok = %v, want %v", tt.line, ok, tt.wantOK)
+			}
+			if !ok {
+				return
+			}
+			if tag != tt.tag || arg != tt.arg {
+				t.Fatalf("parseTagLine(%q) = (%q, %q), want (%q, %q)", tt.line, tag, arg, tt.tag, tt.arg)
+			}
+		})
+	}
+}
+
+func TestScanTags(t *testing.T) {
+	t.Run("stray @ does not truncate the block", func(t *testing.T) {
+		text := "@description ping @someone for help\nmore text"
+		blocks := scanTags(text)
+		if len(blocks) != 1 {
+			t.Fatalf("got %d blocks, want 1: %+v", len(blocks), blocks)
+		}
+		got := blocks[0].arg + "\n" + blocks[0].body
+		want := "ping @someone for help\nmore text"
+		if got != want {
+			t.Fatalf("description block = %q, want %q", got, want)
+		}
+	})
+
+	t.Run("multiple blocks and a fenced sample", func(t *testing.T) {
+		text := "@endpoint /v1/users\n" +
+			"@method POST\n" +
+			"@sampleBody\n" +
+			"```json\n" +
+			"{\"name\": \"bob\"}\n" +
+			"```"
+		blocks := scanTags(text)
+
+		got := make([]string, len(blocks))
+		for i, b := range blocks {
+			got[i] = b.tag
+		}
+		want := []string{"endpoint", "method", "sampleBody"}
+		if !reflect.DeepEqual(got, want) {
+			t.Fatalf("tags = %v, want %v", got, want)
+		}
+
+		sample := blocks[2]
+		if sample.lang != "json" {
+			t.Fatalf("sample lang = %q, want %q", sample.lang, "json")
+		}
+		if sample.code != `{"name": "bob"}` {
+			t.Fatalf("sample code = %q", sample.code)
+		}
+	})
+}
@@ -0,0 +1,203 @@
+package main
+
+import (
+	"go/ast"
+	"go/token"
+	"go/types"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// StructField is one field of a request/response type, as rendered in the
+// HTML struct field table and checked by sample validation.
+type StructField struct {
+	Name     string `json:"name"`
+	Type     string `json:"type"`
+	JSON     string `json:"json,omitempty"`
+	Required bool   `json:"required,omitempty"`
+}
+
+// collectTypeSpecs indexes every top-level type declaration in pkg by name,
+// so a RESTDoc/RPCDoc/BroadcastDoc can resolve the struct type it was bound
+// to via its *ast.FuncDecl signature back to its field list.
+func collectTypeSpecs(pkg *ast.Package) map[string]*ast.TypeSpec {
+	specs := make(map[string]*ast.TypeSpec)
+	for _, file := range pkg.Files {
+		for _, decl := range file.Decls {
+			genDecl, ok := decl.(*ast.GenDecl)
+			if !ok || genDecl.Tok != token.TYPE {
+				continue
+			}
+			for _, spec := range genDecl.Specs {
+				if ts, ok := spec.(*ast.TypeSpec); ok {
+					specs[ts.Name.Name] = ts
+				}
+			}
+		}
+	}
+	return specs
+}
+
+// structFields returns the fields of the named struct type, or nil if
+// typeName doesn't resolve to a struct in typesByName.
+func structFields(typeName string, typesByName map[string]*ast.TypeSpec) []StructField {
+	ts, ok := typesByName[typeName]
+	if !ok {
+		return nil
+	}
+	structType, ok := ts.Type.(*ast.StructType)
+	if !ok || structType.Fields == nil {
+		return nil
+	}
+
+	var fields []StructField
+	for _, field := range structType.Fields.List {
+		typeStr := exprString(field.Type)
+		jsonName, jsonTag := "", ""
+		if field.Tag != nil {
+			jsonName, jsonTag = jsonTagParts(field.Tag.Value)
+		}
+		required := !strings.HasPrefix(typeStr, "*") && !strings.Contains(jsonTag, "omitempty")
+
+		if len(field.Names) == 0 {
+			// embedded field
+			fields = append(fields, StructField{Name: typeStr, Type: typeStr, JSON: jsonName, Required: required})
+			continue
+		}
+		for _, name := range field.Names {
+			fields = append(fields, StructField{Name: name.Name, Type: typeStr, JSON: jsonName, Required: required})
+		}
+	}
+
+	return fields
+}
+
+// jsonTagParts extracts the name and full value of a `json:"..."` struct
+// tag, e.g. `json:"id,omitempty"` -> ("id", "id,omitempty").
+func jsonTagParts(rawTag string) (name, jsonTag string) {
+	unquoted, err := strconv.Unquote(rawTag)
+	if err != nil {
+		return "", ""
+	}
+	jsonTag = reflect.StructTag(unquoted).Get("json")
+	if jsonTag == "" {
+		return "", ""
+	}
+	return strings.Split(jsonTag, ",")[0], jsonTag
+}
+
+// exprString renders a (possibly qualified/pointer/slice) type expression
+// as source-like text, e.g. "*[]pkg.Foo".
+func exprString(expr ast.Expr) string {
+	switch t := expr.(type) {
+	case *ast.Ident:
+		return t.Name
+	case *ast.StarExpr:
+		return "*" + exprString(t.X)
+	case *ast.ArrayType:
+		return "[]" + exprString(t.Elt)
+	case *ast.MapType:
+		return "map[" + exprString(t.Key) + "]" + exprString(t.Value)
+	case *ast.SelectorExpr:
+		return exprString(t.X) + "." + t.Sel.Name
+	case *ast.InterfaceType:
+		return "interface{}"
+	default:
+		return "?"
+	}
+}
+
+// resolveStructFields returns the fields of typeName, preferring the
+// precise types.Struct resolved by go/types when typesPkg type-checked
+// successfully, and falling back to the plain AST reading of the type
+// declaration otherwise (e.g. when an import couldn't be resolved).
+func resolveStructFields(typeName string, typesPkg *types.Package, typesByName map[string]*ast.TypeSpec) []StructField {
+	if fields := structFieldsFromTypesPkg(typeName, typesPkg); fields != nil {
+		return fields
+	}
+	return structFields(typeName, typesByName)
+}
+
+// structFieldsFromTypesPkg resolves typeName to a *types.Struct via
+// typesPkg's package scope and reads its fields, including tag
+// information, the way go/types sees it after full type-checking.
+func structFieldsFromTypesPkg(typeName string, typesPkg *types.Package) []StructField {
+	if typesPkg == nil {
+		return nil
+	}
+	obj := typesPkg.Scope().Lookup(typeName)
+	if obj == nil {
+		return nil
+	}
+	named, ok := obj.Type().(*types.Named)
+	if !ok {
+		return nil
+	}
+	structType, ok := named.Underlying().(*types.Struct)
+	if !ok {
+		return nil
+	}
+
+	var fields []StructField
+	for i := 0; i < structType.NumFields(); i++ {
+		field := structType.Field(i)
+		jsonName, jsonTag := "", reflect.StructTag(structType.Tag(i)).Get("json")
+		if jsonTag != "" {
+			jsonName = strings.Split(jsonTag, ",")[0]
+		}
+		_, isPointer := field.Type().(*types.Pointer)
+		required := !isPointer && !strings.Contains(jsonTag, "omitempty")
+
+		fields = append(fields, StructField{
+			Name:     field.Name(),
+			Type:     field.Type().String(),
+			JSON:     jsonName,
+			Required: required,
+		})
+	}
+
+	return fields
+}
+
+// localIdentName returns the unqualified name of expr if it (optionally
+// through a pointer) is a plain identifier, or "" if it's a qualified type
+// like http.Request. Qualified types can never resolve via
+// typesByName/typesPkg -- both only index declarations in the scanned
+// package -- so they're excluded here rather than left to match a suffix
+// check and shadow the handler's own request/response type.
+func localIdentName(expr ast.Expr) string {
+	if star, ok := expr.(*ast.StarExpr); ok {
+		expr = star.X
+	}
+	ident, ok := expr.(*ast.Ident)
+	if !ok {
+		return ""
+	}
+	return ident.Name
+}
+
+// requestResponseTypeNames inspects a function's signature for a parameter
+// type ending in "Request" and a result type ending in "Response", the
+// naming convention this package's handlers follow.
+func requestResponseTypeNames(fn *ast.FuncDecl) (reqType, respType string) {
+	if fn.Type.Params != nil {
+		for _, field := range fn.Type.Params.List {
+			name := localIdentName(field.Type)
+			if strings.HasSuffix(name, "Request") {
+				reqType = name
+				break
+			}
+		}
+	}
+	if fn.Type.Results != nil {
+		for _, field := range fn.Type.Results.List {
+			name := localIdentName(field.Type)
+			if strings.HasSuffix(name, "Response") {
+				respType = name
+				break
+			}
+		}
+	}
+	return reqType, respType
+}
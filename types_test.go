@@ -0,0 +1,134 @@
+package main
+
+import (
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"testing"
+)
+
+// parseFuncDecl parses src and returns the *ast.FuncDecl named fnName.
+func parseFuncDecl(t *testing.T, src, fnName string) *ast.FuncDecl {
+	t.Helper()
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, "test.go", src, 0)
+	if err != nil {
+		t.Fatalf("ParseFile: %v", err)
+	}
+	for _, decl := range file.Decls {
+		if fn, ok := decl.(*ast.FuncDecl); ok && fn.Name.Name == fnName {
+			return fn
+		}
+	}
+	t.Fatalf("no func %s in source", fnName)
+	return nil
+}
+
+func TestRequestResponseTypeNames(t *testing.T) {
+	tests := []struct {
+		name     string
+		src      string
+		fnName   string
+		wantReq  string
+		wantResp string
+	}{
+		{
+			name: "request and pointer response",
+			src: `package pkg
+func CreateUser(req CreateUserRequest) (*CreateUserResponse, error) { return nil, nil }`,
+			fnName:   "CreateUser",
+			wantReq:  "CreateUserRequest",
+			wantResp: "CreateUserResponse",
+		},
+		{
+			name: "no matching params or results",
+			src: `package pkg
+func Ping() error { return nil }`,
+			fnName:   "Ping",
+			wantReq:  "",
+			wantResp: "",
+		},
+		{
+			name: "response only",
+			src: `package pkg
+func ListUsers() (ListUsersResponse, error) { return ListUsersResponse{}, nil }`,
+			fnName:   "ListUsers",
+			wantReq:  "",
+			wantResp: "ListUsersResponse",
+		},
+		{
+			name: "qualified stdlib param doesn't shadow the real request type",
+			src: `package pkg
+import "net/http"
+func Foo(w http.ResponseWriter, r *http.Request, req FooRequest) (*FooResponse, error) { return nil, nil }`,
+			fnName:   "Foo",
+			wantReq:  "FooRequest",
+			wantResp: "FooResponse",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fn := parseFuncDecl(t, tt.src, tt.fnName)
+			gotReq, gotResp := requestResponseTypeNames(fn)
+			if gotReq != tt.wantReq || gotResp != tt.wantResp {
+				t.Fatalf("requestResponseTypeNames() = (%q, %q), want (%q, %q)", gotReq, gotResp, tt.wantReq, tt.wantResp)
+			}
+		})
+	}
+}
+
+func TestStructFields(t *testing.T) {
+	src := `package pkg
+
+type CreateUserRequest struct {
+	Name     string  ` + "`json:\"name\"`" + `
+	Nickname *string ` + "`json:\"nickname,omitempty\"`" + `
+	internal string
+}
+`
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, "test.go", src, 0)
+	if err != nil {
+		t.Fatalf("ParseFile: %v", err)
+	}
+	pkg := &ast.Package{Name: "pkg", Files: map[string]*ast.File{"test.go": file}}
+	typesByName := collectTypeSpecs(pkg)
+
+	fields := structFields("CreateUserRequest", typesByName)
+
+	byName := make(map[string]StructField, len(fields))
+	for _, f := range fields {
+		byName[f.Name] = f
+	}
+
+	name, ok := byName["Name"]
+	if !ok {
+		t.Fatalf("missing field Name in %+v", fields)
+	}
+	if name.JSON != "name" || !name.Required {
+		t.Fatalf("Name field = %+v, want JSON=name Required=true", name)
+	}
+
+	nickname, ok := byName["Nickname"]
+	if !ok {
+		t.Fatalf("missing field Nickname in %+v", fields)
+	}
+	if nickname.JSON != "nickname" || nickname.Required {
+		t.Fatalf("Nickname field = %+v, want JSON=nickname Required=false", nickname)
+	}
+
+	internal, ok := byName["internal"]
+	if !ok {
+		t.Fatalf("missing field internal in %+v", fields)
+	}
+	if !internal.Required {
+		t.Fatalf("internal field = %+v, want Required=true (no json tag, no omitempty)", internal)
+	}
+}
+
+func TestStructFieldsUnknownType(t *testing.T) {
+	if fields := structFields("DoesNotExist", map[string]*ast.TypeSpec{}); fields != nil {
+		t.Fatalf("structFields() = %+v, want nil", fields)
+	}
+}
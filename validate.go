@@ -0,0 +1,194 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"go/ast"
+	"go/importer"
+	"go/token"
+	"go/types"
+	"strings"
+)
+
+// checkTypes type-checks astPkg with go/types, resolving its imports with
+// go/importer, so request/response struct fields can be validated against
+// the types the compiler actually sees rather than just their AST shape.
+// It returns nil if the package doesn't type-check -- most commonly
+// because an import isn't resolvable in the current build environment --
+// in which case callers fall back to the plain AST reading of the type
+// declaration.
+func checkTypes(fset *token.FileSet, astPkg *ast.Package) *types.Package {
+	var files []*ast.File
+	for _, file := range astPkg.Files {
+		files = append(files, file)
+	}
+
+	conf := types.Config{
+		Importer: importer.ForCompiler(fset, "source", nil),
+		Error:    func(error) {}, // collect nothing; a failed check just means no typesPkg
+	}
+	typesPkg, err := conf.Check(astPkg.Name, fset, files, nil)
+	if err != nil {
+		return nil
+	}
+	return typesPkg
+}
+
+// sampleViolation is one way a @sampleBody/@sampleResponse didn't match
+// the request/response type inferred for its declaration.
+type sampleViolation struct {
+	Pos     token.Position
+	Message string
+}
+
+func (v sampleViolation) String() string {
+	return fmt.Sprintf("%s: %s", v.Pos, v.Message)
+}
+
+// validateDocSamples checks every sample in pkgDocs against the
+// request/response type resolved for its doc, and reports drift between
+// the docs and the code: unknown fields, missing required fields, and
+// type mismatches.
+func validateDocSamples(pkgDocs map[string]*PackageDoc) []sampleViolation {
+	var violations []sampleViolation
+
+	for _, pkgDoc := range pkgDocs {
+		for _, rd := range pkgDoc.RESTDocs {
+			violations = append(violations, validateSamples(rd.Samples, rd.reqTypeName, rd.respTypeName, rd.typesPkg, rd.typesByName, rd.pos)...)
+		}
+		for _, rpcDoc := range pkgDoc.RPCDocs {
+			violations = append(violations, validateSamples(rpcDoc.Samples, rpcDoc.reqTypeName, rpcDoc.respTypeName, rpcDoc.typesPkg, rpcDoc.typesByName, rpcDoc.pos)...)
+		}
+		for _, bcastDoc := range pkgDoc.BroadcastDocs {
+			violations = append(violations, validateSamples(bcastDoc.Samples, "", bcastDoc.respTypeName, bcastDoc.typesPkg, bcastDoc.typesByName, bcastDoc.pos)...)
+		}
+	}
+
+	return violations
+}
+
+func validateSamples(samples []DocSample, reqType, respType string, typesPkg *types.Package, typesByName map[string]*ast.TypeSpec, pos token.Position) []sampleViolation {
+	var violations []sampleViolation
+
+	for _, sample := range samples {
+		typeName := respType
+		if sample.Type == sampleTypeBody {
+			typeName = reqType
+		}
+		if typeName == "" {
+			continue
+		}
+
+		fields := resolveStructFields(typeName, typesPkg, typesByName)
+		if fields == nil {
+			continue
+		}
+
+		var decoded map[string]interface{}
+		if err := json.Unmarshal([]byte(sample.Code), &decoded); err != nil {
+			violations = append(violations, sampleViolation{
+				Pos:     pos,
+				Message: fmt.Sprintf("sample for %s is not valid JSON: %v", typeName, err),
+			})
+			continue
+		}
+
+		violations = append(violations, checkSampleFields(decoded, fields, typeName, pos)...)
+	}
+
+	return violations
+}
+
+// checkSampleFields compares a decoded JSON object against a struct's
+// fields, reporting any sample key the struct doesn't declare, any required
+// struct field the sample lacks (optional fields may be absent), and any
+// field whose JSON value's shape doesn't match the struct field's Go type.
+func checkSampleFields(decoded map[string]interface{}, fields []StructField, typeName string, pos token.Position) []sampleViolation {
+	var violations []sampleViolation
+
+	byJSONName := make(map[string]StructField, len(fields))
+	for _, field := range fields {
+		name := field.JSON
+		if name == "" {
+			name = field.Name
+		}
+		if name == "-" {
+			continue
+		}
+		byJSONName[name] = field
+	}
+
+	for key := range decoded {
+		if _, ok := byJSONName[key]; !ok {
+			violations = append(violations, sampleViolation{
+				Pos:     pos,
+				Message: fmt.Sprintf("%s: sample has unknown field %q", typeName, key),
+			})
+		}
+	}
+
+	for name, field := range byJSONName {
+		value, present := decoded[name]
+		if !present {
+			if field.Required {
+				violations = append(violations, sampleViolation{
+					Pos:     pos,
+					Message: fmt.Sprintf("%s: sample is missing required field %q", typeName, name),
+				})
+			}
+			continue
+		}
+		if msg := typeMismatch(field.Type, value); msg != "" {
+			violations = append(violations, sampleViolation{
+				Pos:     pos,
+				Message: fmt.Sprintf("%s.%s: %s", typeName, name, msg),
+			})
+		}
+	}
+
+	return violations
+}
+
+// typeMismatch reports a human-readable mismatch between a struct field's
+// Go type and the shape of its decoded JSON value, or "" if they agree.
+func typeMismatch(goType string, value interface{}) string {
+	if value == nil {
+		return ""
+	}
+	goType = strings.TrimPrefix(goType, "*")
+
+	switch {
+	case strings.HasPrefix(goType, "[]"):
+		if _, ok := value.([]interface{}); !ok {
+			return fmt.Sprintf("expected %s, got %T", goType, value)
+		}
+	case strings.HasPrefix(goType, "map["):
+		if _, ok := value.(map[string]interface{}); !ok {
+			return fmt.Sprintf("expected %s, got %T", goType, value)
+		}
+	case goType == "string":
+		if _, ok := value.(string); !ok {
+			return fmt.Sprintf("expected string, got %T", value)
+		}
+	case goType == "bool":
+		if _, ok := value.(bool); !ok {
+			return fmt.Sprintf("expected bool, got %T", value)
+		}
+	case isNumericGoType(goType):
+		if _, ok := value.(float64); !ok {
+			return fmt.Sprintf("expected %s, got %T", goType, value)
+		}
+	}
+	return ""
+}
+
+func isNumericGoType(goType string) bool {
+	switch goType {
+	case "int", "int8", "int16", "int32", "int64",
+		"uint", "uint8", "uint16", "uint32", "uint64",
+		"float32", "float64":
+		return true
+	default:
+		return false
+	}
+}